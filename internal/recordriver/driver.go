@@ -5,9 +5,12 @@
 package recordriver
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"io"
+	"os"
+	"regexp"
 	"strings"
 	"sync"
 )
@@ -24,9 +27,33 @@ var (
 type (
 	// session is a session of recordriver which records queries and statements.
 	session struct {
-		Queries    []string
-		Statements []string
+		Queries    []Entry
+		Statements []Entry
 		responses  map[string]*Response
+		patterns   []patternResponse
+		sequences  map[string]*sequence
+		// filePath is set when the session was opened with a "file:" DSN, and causes the
+		// session to be flushed back to disk when its connection is closed.
+		filePath string
+	}
+	// patternResponse pairs a compiled regexp with the response served to queries it matches.
+	patternResponse struct {
+		re   *regexp.Regexp
+		resp *Response
+	}
+	// sequence is a queue of responses popped one at a time by successive matching queries,
+	// registered via SetResponseSequence or SetResponseCycle.
+	sequence struct {
+		resps []*Response
+		cycle bool
+		pos   int
+	}
+	// Entry is a single recorded query or statement, including the bound arguments and the
+	// context it was executed with.
+	Entry struct {
+		SQL  string
+		Args []driver.NamedValue
+		Ctx  context.Context
 	}
 	// Response is a response to a query.
 	Response struct {
@@ -41,7 +68,9 @@ type (
 		query   string
 		session string
 	}
-	tx          struct{}
+	tx struct {
+		session string
+	}
 	emptyResult struct{}
 )
 
@@ -49,12 +78,24 @@ type (
 func (s *session) Stmts() string {
 	var sb strings.Builder
 	for _, stmt := range s.Statements {
-		sb.WriteString(stmt)
+		sb.WriteString(stmt.SQL)
 		sb.WriteString(";\n")
 	}
 	return sb.String()
 }
 
+// StatementEntries returns the typed statement entries recorded in this session, including the
+// arguments gorm bound for each one.
+func (s *session) StatementEntries() []Entry {
+	return s.Statements
+}
+
+// QueryEntries returns the typed query entries recorded in this session, including the
+// arguments gorm bound for each one.
+func (s *session) QueryEntries() []Entry {
+	return s.Queries
+}
+
 // Session returns the session with the given name and reports whether it exists.
 func Session(name string) (*session, bool) {
 	mu.Lock()
@@ -63,26 +104,122 @@ func Session(name string) (*session, bool) {
 	return h, ok
 }
 
+// ensureSession returns the session registered under name, creating an empty one if it doesn't
+// exist yet. Callers must hold mu.
+func ensureSession(name string) *session {
+	sess, ok := sessions[name]
+	if !ok {
+		sess = &session{responses: make(map[string]*Response)}
+		sessions[name] = sess
+	}
+	return sess
+}
+
 // SetResponse sets the response for the given session and query.
 func SetResponse(s string, query string, resp *Response) {
 	mu.Lock()
 	defer mu.Unlock()
-	if _, ok := sessions[s]; !ok {
-		sessions[s] = &session{
-			responses: make(map[string]*Response),
+	ensureSession(s).responses[query] = resp
+}
+
+// SetResponsePattern registers resp to be served to any query matching re, for queries that miss
+// the exact-match table populated by SetResponse. Patterns are checked in the order they were
+// registered and the first match wins.
+func SetResponsePattern(s string, re *regexp.Regexp, resp *Response) {
+	mu.Lock()
+	defer mu.Unlock()
+	sess := ensureSession(s)
+	sess.patterns = append(sess.patterns, patternResponse{re: re, resp: resp})
+}
+
+// SetResponseSequence registers resps to be served one at a time, in order, to successive
+// queries matching query. Once exhausted, further matching queries get an empty Response.
+func SetResponseSequence(s string, query string, resps ...*Response) {
+	mu.Lock()
+	defer mu.Unlock()
+	sess := ensureSession(s)
+	if sess.sequences == nil {
+		sess.sequences = make(map[string]*sequence)
+	}
+	sess.sequences[query] = &sequence{resps: resps}
+}
+
+// SetResponseCycle is like SetResponseSequence, but wraps back around to the first response
+// instead of returning empty rows once the sequence is exhausted.
+func SetResponseCycle(s string, query string, resps ...*Response) {
+	mu.Lock()
+	defer mu.Unlock()
+	sess := ensureSession(s)
+	if sess.sequences == nil {
+		sess.sequences = make(map[string]*sequence)
+	}
+	sess.sequences[query] = &sequence{resps: resps, cycle: true}
+}
+
+// next pops the next response off the sequence, wrapping around to the start if cycle is set.
+// It reports false once a non-cycling sequence is exhausted.
+func (sq *sequence) next() (*Response, bool) {
+	if len(sq.resps) == 0 {
+		return nil, false
+	}
+	if sq.pos >= len(sq.resps) {
+		if !sq.cycle {
+			return nil, false
+		}
+		sq.pos = 0
+	}
+	resp := sq.resps[sq.pos]
+	sq.pos++
+	return resp, true
+}
+
+// response resolves the driver.Rows to serve for query: a registered sequence takes precedence,
+// then an exact match from SetResponse, then the first matching pattern from
+// SetResponsePattern. It always returns a Response independent of the stored fixture, so a
+// single registration can be served to repeated queries. Callers must hold mu.
+func (s *session) response(query string) *Response {
+	if sq, ok := s.sequences[query]; ok {
+		if resp, ok := sq.next(); ok {
+			return resp.clone()
+		}
+		return &Response{}
+	}
+	if resp, ok := s.responses[query]; ok {
+		return resp.clone()
+	}
+	for _, p := range s.patterns {
+		if p.re.MatchString(query) {
+			return p.resp.clone()
 		}
 	}
-	sessions[s].responses[query] = resp
+	return &Response{}
 }
 
-// Open returns a new connection to the database.
+// Open returns a new connection to the database. A name of the form "file:<path>" loads the
+// session (and its fixture responses) from the JSON file at <path>, flushing it back on Close.
+// This happens only when no session is registered under name yet: a session already created
+// under name, for example by SetResponse before the first connection, is used as is and is
+// neither loaded from nor flushed to the file.
 func (d *drv) Open(name string) (driver.Conn, error) {
 	mu.Lock()
 	defer mu.Unlock()
 	if _, ok := sessions[name]; !ok {
-		sessions[name] = &session{
-			responses: make(map[string]*Response),
+		sess := &session{responses: make(map[string]*Response)}
+		if path, ok := fileDSNPath(name); ok {
+			sess.filePath = path
+			loaded, err := LoadSession(path)
+			if err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+			if loaded != nil {
+				sess.Queries = loaded.Queries
+				sess.Statements = loaded.Statements
+				sess.responses = loaded.responses
+				sess.patterns = loaded.patterns
+				sess.sequences = loaded.sequences
+			}
 		}
+		sessions[name] = sess
 	}
 	return &conn{session: name}, nil
 }
@@ -92,27 +229,106 @@ func (c *conn) Prepare(query string) (driver.Stmt, error) {
 	return &stmt{query: query, session: c.session}, nil
 }
 
-// Close closes the connection.
+// PrepareContext returns a prepared statement, bound to this connection. It implements
+// driver.ConnPrepareContext.
+func (c *conn) PrepareContext(_ context.Context, query string) (driver.Stmt, error) {
+	return &stmt{query: query, session: c.session}, nil
+}
+
+// Close closes the connection, flushing the session back to its fixture file first if it was
+// opened with a "file:" DSN.
 func (c *conn) Close() error {
 	mu.Lock()
 	defer mu.Unlock()
+	sess, ok := sessions[c.session]
+	if ok && sess.filePath != "" {
+		if err := sess.Save(sess.filePath); err != nil {
+			return err
+		}
+	}
 	delete(sessions, c.session)
 	return nil
 }
 
-// Begin starts and returns a new transaction.
+// Begin starts and returns a new transaction, recording a plain BEGIN statement. Callers that
+// need to specify an isolation level or read-only mode should use BeginTx instead.
 func (c *conn) Begin() (driver.Tx, error) {
-	return &tx{}, nil
+	mu.Lock()
+	defer mu.Unlock()
+	sessions[c.session].Statements = append(sessions[c.session].Statements, Entry{SQL: "BEGIN"})
+	return &tx{session: c.session}, nil
 }
 
-// Commit commits the transaction. It is a noop.
-func (*tx) Commit() error {
-	return nil
+// Exec executes a query that doesn't return rows, bypassing the prepare round-trip. It
+// implements driver.Execer.
+func (c *conn) Exec(query string, args []driver.Value) (driver.Result, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	sessions[c.session].Statements = append(sessions[c.session].Statements, Entry{
+		SQL:  query,
+		Args: namedValues(args),
+	})
+	return emptyResult{}, nil
 }
 
-// Rollback rolls back the transaction. It is a noop.
-func (*tx) Rollback() error {
-	return nil
+// ExecContext executes a query that doesn't return rows, bypassing the prepare round-trip. It
+// implements driver.ExecerContext.
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	sessions[c.session].Statements = append(sessions[c.session].Statements, Entry{
+		SQL:  query,
+		Args: args,
+		Ctx:  ctx,
+	})
+	return emptyResult{}, nil
+}
+
+// Query executes a query that may return rows, bypassing the prepare round-trip. It implements
+// driver.Queryer.
+func (c *conn) Query(query string, args []driver.Value) (driver.Rows, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	return c.query(query, namedValues(args))
+}
+
+// QueryContext executes a query that may return rows, bypassing the prepare round-trip. It
+// implements driver.QueryerContext.
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	return c.query(query, args, ctx)
+}
+
+// query records the query against the connection's session and returns its registered response,
+// if any. Callers must hold mu.
+func (c *conn) query(query string, args []driver.NamedValue, ctx ...context.Context) (driver.Rows, error) {
+	sess := sessions[c.session]
+	entry := Entry{SQL: query, Args: args}
+	if len(ctx) > 0 {
+		entry.Ctx = ctx[0]
+	}
+	sess.Queries = append(sess.Queries, entry)
+	return sess.response(query), nil
+}
+
+// namedValues converts the legacy []driver.Value arguments into []driver.NamedValue, assigning
+// each one its positional ordinal.
+func namedValues(args []driver.Value) []driver.NamedValue {
+	if len(args) == 0 {
+		return nil
+	}
+	nv := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		nv[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return nv
 }
 
 // Close closes the statement.
@@ -127,23 +343,34 @@ func (*stmt) NumInput() int {
 }
 
 // Exec executes a query that doesn't return rows, such as an CREATE or ALTER TABLE.
-func (s *stmt) Exec(_ []driver.Value) (driver.Result, error) {
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
 	mu.Lock()
 	defer mu.Unlock()
-	sessions[s.session].Statements = append(sessions[s.session].Statements, s.query)
+	sessions[s.session].Statements = append(sessions[s.session].Statements, Entry{
+		SQL:  s.query,
+		Args: namedValues(args),
+	})
 	return emptyResult{}, nil
 }
 
 // Query executes a query that may return rows, such as an SELECT.
-func (s *stmt) Query(_ []driver.Value) (driver.Rows, error) {
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
 	mu.Lock()
 	defer mu.Unlock()
-	sess := s.session
-	sessions[sess].Queries = append(sessions[sess].Queries, s.query)
-	if resp, ok := sessions[sess].responses[s.query]; ok {
-		return resp, nil
-	}
-	return &Response{}, nil
+	sess := sessions[s.session]
+	sess.Queries = append(sess.Queries, Entry{
+		SQL:  s.query,
+		Args: namedValues(args),
+	})
+	return sess.response(s.query), nil
+}
+
+// clone returns a shallow copy of r whose Data slice is independent of the stored fixture, so
+// that Next can consume it without mutating the registered response.
+func (r *Response) clone() *Response {
+	data := make([][]driver.Value, len(r.Data))
+	copy(data, r.Data)
+	return &Response{Cols: r.Cols, Data: data}
 }
 
 // Columns returns the names of the columns in the result set.
@@ -176,4 +403,4 @@ func (emptyResult) LastInsertId() (int64, error) {
 // value of 0.
 func (emptyResult) RowsAffected() (int64, error) {
 	return 0, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,76 @@
+package recordriver
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+func TestExecQueryCaptureArgs(t *testing.T) {
+	db, err := sql.Open("recordriver", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("INSERT INTO foo VALUES (?, ?)", 1, "bar"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Query("SELECT * FROM foo WHERE id = ?", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	sess, ok := Session(t.Name())
+	if !ok {
+		t.Fatal("expected session to exist")
+	}
+
+	stmts := sess.StatementEntries()
+	if len(stmts) != 1 {
+		t.Fatalf("want 1 statement, got %d", len(stmts))
+	}
+	if len(stmts[0].Args) != 2 || stmts[0].Args[0].Value != int64(1) || stmts[0].Args[1].Value != "bar" {
+		t.Fatalf("args not captured: %+v", stmts[0].Args)
+	}
+
+	queries := sess.QueryEntries()
+	if len(queries) != 1 {
+		t.Fatalf("want 1 query, got %d", len(queries))
+	}
+	if len(queries[0].Args) != 1 || queries[0].Args[0].Value != int64(1) {
+		t.Fatalf("query args not captured: %+v", queries[0].Args)
+	}
+}
+
+func TestQueryContextCancellation(t *testing.T) {
+	db, err := sql.Open("recordriver", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// Force the connection open (and the session registered) before the context is cancelled, so
+	// the session exists to be inspected below. database/sql itself returns ctx.Err() for a done
+	// context before handing out a connection, so the cancelled calls never reach the driver.
+	if err := db.PingContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := db.QueryContext(ctx, "SELECT 1"); err == nil {
+		t.Fatal("expected error from cancelled context")
+	}
+	if _, err := db.ExecContext(ctx, "INSERT INTO foo VALUES (1)"); err == nil {
+		t.Fatal("expected error from cancelled context")
+	}
+
+	sess, ok := Session(t.Name())
+	if !ok {
+		t.Fatal("expected session to exist")
+	}
+	if len(sess.QueryEntries()) != 0 || len(sess.StatementEntries()) != 0 {
+		t.Fatalf("cancelled calls should not be recorded, got queries=%v statements=%v", sess.QueryEntries(), sess.StatementEntries())
+	}
+}
@@ -0,0 +1,326 @@
+package recordriver
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// fileDSNPrefix marks a DSN passed to sql.Open("recordriver", name) as file-backed: the session
+// is loaded from (and flushed back to) the JSON file at the path following the prefix.
+const fileDSNPrefix = "file:"
+
+// fileDSNPath reports whether name is a file-backed DSN and, if so, returns the path it names.
+func fileDSNPath(name string) (string, bool) {
+	if !strings.HasPrefix(name, fileDSNPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(name, fileDSNPrefix), true
+}
+
+// fileSession is the on-disk representation of a session, written and read by Save and
+// LoadSession.
+type fileSession struct {
+	Queries    []fileEntry             `json:"queries,omitempty"`
+	Statements []fileEntry             `json:"statements,omitempty"`
+	Responses  map[string]fileResponse `json:"responses,omitempty"`
+	Patterns   []filePattern           `json:"patterns,omitempty"`
+	Sequences  map[string]fileSequence `json:"sequences,omitempty"`
+}
+
+// filePattern is the on-disk representation of a patternResponse registered via
+// SetResponsePattern. The regexp is stored as its source so it can be recompiled on load.
+type filePattern struct {
+	Pattern  string       `json:"pattern"`
+	Response fileResponse `json:"response"`
+}
+
+// fileSequence is the on-disk representation of a sequence registered via SetResponseSequence
+// or SetResponseCycle, including its current position so a partially-consumed sequence resumes
+// correctly on reload.
+type fileSequence struct {
+	Responses []fileResponse `json:"responses,omitempty"`
+	Cycle     bool           `json:"cycle,omitempty"`
+	Pos       int            `json:"pos,omitempty"`
+}
+
+// fileEntry is the on-disk representation of an Entry. The Ctx field is dropped: a
+// context.Context cannot be serialized and is meaningless once replayed in a later run.
+type fileEntry struct {
+	SQL  string           `json:"sql"`
+	Args []fileNamedValue `json:"args,omitempty"`
+}
+
+// fileNamedValue is the on-disk representation of a driver.NamedValue.
+type fileNamedValue struct {
+	Name    string    `json:"name,omitempty"`
+	Ordinal int       `json:"ordinal"`
+	Value   fileValue `json:"value"`
+}
+
+// fileResponse is the on-disk representation of a Response.
+type fileResponse struct {
+	Cols []string      `json:"cols,omitempty"`
+	Data [][]fileValue `json:"data,omitempty"`
+}
+
+// fileValue is a tagged union encoding of a driver.Value, whose dynamic type is otherwise lost
+// to encoding/json. Supported types mirror the ones driver.Value itself allows: int64, float64,
+// bool, []byte, string, time.Time, and nil.
+type fileValue struct {
+	Type string          `json:"type"`
+	V    json.RawMessage `json:"v,omitempty"`
+}
+
+// encodeValue converts a driver.Value into its tagged-union JSON form.
+func encodeValue(v driver.Value) (fileValue, error) {
+	switch t := v.(type) {
+	case nil:
+		return fileValue{Type: "null"}, nil
+	case int64:
+		raw, err := json.Marshal(t)
+		return fileValue{Type: "int64", V: raw}, err
+	case float64:
+		raw, err := json.Marshal(t)
+		return fileValue{Type: "float64", V: raw}, err
+	case bool:
+		raw, err := json.Marshal(t)
+		return fileValue{Type: "bool", V: raw}, err
+	case []byte:
+		raw, err := json.Marshal(base64.StdEncoding.EncodeToString(t))
+		return fileValue{Type: "bytes", V: raw}, err
+	case string:
+		raw, err := json.Marshal(t)
+		return fileValue{Type: "string", V: raw}, err
+	case time.Time:
+		raw, err := json.Marshal(t.Format(time.RFC3339Nano))
+		return fileValue{Type: "time", V: raw}, err
+	default:
+		return fileValue{}, fmt.Errorf("recordriver: unsupported value type %T", v)
+	}
+}
+
+// decodeValue converts a tagged-union JSON value back into a driver.Value.
+func decodeValue(fv fileValue) (driver.Value, error) {
+	switch fv.Type {
+	case "null", "":
+		return nil, nil
+	case "int64":
+		var v int64
+		err := json.Unmarshal(fv.V, &v)
+		return v, err
+	case "float64":
+		var v float64
+		err := json.Unmarshal(fv.V, &v)
+		return v, err
+	case "bool":
+		var v bool
+		err := json.Unmarshal(fv.V, &v)
+		return v, err
+	case "bytes":
+		var s string
+		if err := json.Unmarshal(fv.V, &s); err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.DecodeString(s)
+	case "string":
+		var v string
+		err := json.Unmarshal(fv.V, &v)
+		return v, err
+	case "time":
+		var s string
+		if err := json.Unmarshal(fv.V, &s); err != nil {
+			return nil, err
+		}
+		return time.Parse(time.RFC3339Nano, s)
+	default:
+		return nil, fmt.Errorf("recordriver: unknown value type %q", fv.Type)
+	}
+}
+
+func encodeEntry(e Entry) (fileEntry, error) {
+	fe := fileEntry{SQL: e.SQL}
+	if len(e.Args) == 0 {
+		return fe, nil
+	}
+	fe.Args = make([]fileNamedValue, len(e.Args))
+	for i, nv := range e.Args {
+		fv, err := encodeValue(nv.Value)
+		if err != nil {
+			return fileEntry{}, err
+		}
+		fe.Args[i] = fileNamedValue{Name: nv.Name, Ordinal: nv.Ordinal, Value: fv}
+	}
+	return fe, nil
+}
+
+func decodeEntry(fe fileEntry) (Entry, error) {
+	e := Entry{SQL: fe.SQL}
+	if len(fe.Args) == 0 {
+		return e, nil
+	}
+	e.Args = make([]driver.NamedValue, len(fe.Args))
+	for i, fnv := range fe.Args {
+		v, err := decodeValue(fnv.Value)
+		if err != nil {
+			return Entry{}, err
+		}
+		e.Args[i] = driver.NamedValue{Name: fnv.Name, Ordinal: fnv.Ordinal, Value: v}
+	}
+	return e, nil
+}
+
+// encodeResponse converts a Response into its on-disk form.
+func encodeResponse(resp *Response) (fileResponse, error) {
+	fr := fileResponse{Cols: resp.Cols}
+	for _, row := range resp.Data {
+		fr.Data = append(fr.Data, nil)
+		frow := &fr.Data[len(fr.Data)-1]
+		for _, v := range row {
+			fv, err := encodeValue(v)
+			if err != nil {
+				return fileResponse{}, err
+			}
+			*frow = append(*frow, fv)
+		}
+	}
+	return fr, nil
+}
+
+// decodeResponse converts an on-disk response back into a Response.
+func decodeResponse(fr fileResponse) (*Response, error) {
+	resp := &Response{Cols: fr.Cols}
+	for _, frow := range fr.Data {
+		row := make([]driver.Value, len(frow))
+		for i, fv := range frow {
+			v, err := decodeValue(fv)
+			if err != nil {
+				return nil, err
+			}
+			row[i] = v
+		}
+		resp.Data = append(resp.Data, row)
+	}
+	return resp, nil
+}
+
+// Save serializes the session's queries, statements, and registered responses — including
+// patterns and sequences registered via SetResponsePattern, SetResponseSequence, and
+// SetResponseCycle — to the JSON file at path, creating or truncating it as needed.
+func (s *session) Save(path string) error {
+	fs := fileSession{
+		Responses: make(map[string]fileResponse, len(s.responses)),
+		Sequences: make(map[string]fileSequence, len(s.sequences)),
+	}
+	for _, e := range s.Queries {
+		fe, err := encodeEntry(e)
+		if err != nil {
+			return err
+		}
+		fs.Queries = append(fs.Queries, fe)
+	}
+	for _, e := range s.Statements {
+		fe, err := encodeEntry(e)
+		if err != nil {
+			return err
+		}
+		fs.Statements = append(fs.Statements, fe)
+	}
+	for query, resp := range s.responses {
+		fr, err := encodeResponse(resp)
+		if err != nil {
+			return err
+		}
+		fs.Responses[query] = fr
+	}
+	for _, p := range s.patterns {
+		fr, err := encodeResponse(p.resp)
+		if err != nil {
+			return err
+		}
+		fs.Patterns = append(fs.Patterns, filePattern{Pattern: p.re.String(), Response: fr})
+	}
+	for query, sq := range s.sequences {
+		fseq := fileSequence{Cycle: sq.cycle, Pos: sq.pos}
+		for _, resp := range sq.resps {
+			fr, err := encodeResponse(resp)
+			if err != nil {
+				return err
+			}
+			fseq.Responses = append(fseq.Responses, fr)
+		}
+		fs.Sequences[query] = fseq
+	}
+	data, err := json.MarshalIndent(fs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// LoadSession reads a session previously written by Save from the JSON file at path. It does
+// not register the session under a name; pair it with sql.Open("recordriver", "file:"+path) to
+// do that automatically, or call SetResponse-style registration yourself.
+func LoadSession(path string) (*session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fs fileSession
+	if err := json.Unmarshal(data, &fs); err != nil {
+		return nil, err
+	}
+	s := &session{
+		responses: make(map[string]*Response, len(fs.Responses)),
+		sequences: make(map[string]*sequence, len(fs.Sequences)),
+	}
+	for _, fe := range fs.Queries {
+		e, err := decodeEntry(fe)
+		if err != nil {
+			return nil, err
+		}
+		s.Queries = append(s.Queries, e)
+	}
+	for _, fe := range fs.Statements {
+		e, err := decodeEntry(fe)
+		if err != nil {
+			return nil, err
+		}
+		s.Statements = append(s.Statements, e)
+	}
+	for query, fr := range fs.Responses {
+		resp, err := decodeResponse(fr)
+		if err != nil {
+			return nil, err
+		}
+		s.responses[query] = resp
+	}
+	for _, fp := range fs.Patterns {
+		re, err := regexp.Compile(fp.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := decodeResponse(fp.Response)
+		if err != nil {
+			return nil, err
+		}
+		s.patterns = append(s.patterns, patternResponse{re: re, resp: resp})
+	}
+	for query, fseq := range fs.Sequences {
+		sq := &sequence{cycle: fseq.Cycle, pos: fseq.Pos}
+		for _, fr := range fseq.Responses {
+			resp, err := decodeResponse(fr)
+			if err != nil {
+				return nil, err
+			}
+			sq.resps = append(sq.resps, resp)
+		}
+		s.sequences[query] = sq
+	}
+	return s, nil
+}
@@ -0,0 +1,132 @@
+package recordriver
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileBackedSessionRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	name := "file:" + path
+
+	db, err := sql.Open("recordriver", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Open the connection first so the session is file-backed before SetResponse registers it.
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+
+	when := time.Date(2026, 7, 29, 12, 0, 0, 0, time.UTC)
+	SetResponse(name, "SELECT * FROM widgets", &Response{
+		Cols: []string{"id", "price", "active", "label", "blob", "created_at", "deleted_at"},
+		Data: [][]driver.Value{
+			{int64(1), float64(9.99), true, "widget", []byte("raw"), when, nil},
+		},
+	})
+	if _, err := db.Exec("CREATE TABLE widgets (id int)"); err != nil {
+		t.Fatal(err)
+	}
+	rows0, err := db.Query("SELECT * FROM widgets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows0.Close()
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Re-opening the same file-backed DSN should replay the fixture response and recall the
+	// previously recorded statement without calling SetResponse again.
+	db2, err := sql.Open("recordriver", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db2.Close()
+	if err := db2.Ping(); err != nil {
+		t.Fatal(err)
+	}
+
+	sess, ok := Session(name)
+	if !ok {
+		t.Fatal("expected session to exist")
+	}
+	if len(sess.StatementEntries()) != 1 || sess.StatementEntries()[0].SQL != "CREATE TABLE widgets (id int)" {
+		t.Fatalf("statements not reloaded: %+v", sess.StatementEntries())
+	}
+
+	rows, err := db2.Query("SELECT * FROM widgets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		t.Fatal("expected a row from the reloaded fixture")
+	}
+	var (
+		id        int64
+		price     float64
+		active    bool
+		label     string
+		blob      []byte
+		createdAt time.Time
+		deletedAt sql.NullTime
+	)
+	if err := rows.Scan(&id, &price, &active, &label, &blob, &createdAt, &deletedAt); err != nil {
+		t.Fatal(err)
+	}
+	if id != 1 || price != 9.99 || !active || label != "widget" || string(blob) != "raw" || !createdAt.Equal(when) || deletedAt.Valid {
+		t.Fatalf("round-tripped row mismatch: id=%d price=%v active=%v label=%q blob=%q created_at=%v deleted_at=%v",
+			id, price, active, label, blob, createdAt, deletedAt)
+	}
+}
+
+func TestEncodeDecodeValueTaggedUnion(t *testing.T) {
+	when := time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+	cases := []struct {
+		name string
+		v    driver.Value
+	}{
+		{"null", nil},
+		{"int64", int64(42)},
+		{"float64", float64(3.5)},
+		{"bool", true},
+		{"bytes", []byte("hello")},
+		{"string", "foo"},
+		{"time", when},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fv, err := encodeValue(c.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fv.Type != c.name {
+				t.Fatalf("want type %q, got %q", c.name, fv.Type)
+			}
+			got, err := decodeValue(fv)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if t1, ok := c.v.(time.Time); ok {
+				if !got.(time.Time).Equal(t1) {
+					t.Fatalf("want %v, got %v", t1, got)
+				}
+				return
+			}
+			if b, ok := c.v.([]byte); ok {
+				if string(got.([]byte)) != string(b) {
+					t.Fatalf("want %q, got %q", b, got)
+				}
+				return
+			}
+			if got != c.v {
+				t.Fatalf("want %v, got %v", c.v, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package recordriver
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func scanInt64(t *testing.T, rows *sql.Rows) int64 {
+	t.Helper()
+	if !rows.Next() {
+		t.Fatal("expected a row")
+	}
+	var v int64
+	if err := rows.Scan(&v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestSetResponseServesRepeatedQueries(t *testing.T) {
+	db, err := sql.Open("recordriver", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	SetResponse(t.Name(), "SELECT fixed", &Response{Cols: []string{"a"}, Data: [][]driver.Value{{int64(7)}}})
+
+	for i := 0; i < 3; i++ {
+		rows, err := db.Query("SELECT fixed")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := scanInt64(t, rows); got != 7 {
+			t.Fatalf("iteration %d: want 7, got %d", i, got)
+		}
+		rows.Close()
+	}
+}
+
+func TestSetResponseSequenceExhaustsThenEmpties(t *testing.T) {
+	db, err := sql.Open("recordriver", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	SetResponseSequence(t.Name(), "SELECT seq",
+		&Response{Cols: []string{"a"}, Data: [][]driver.Value{{int64(1)}}},
+		&Response{Cols: []string{"a"}, Data: [][]driver.Value{{int64(2)}}},
+	)
+
+	rows, _ := db.Query("SELECT seq")
+	if got := scanInt64(t, rows); got != 1 {
+		t.Fatalf("first call: want 1, got %d", got)
+	}
+	rows.Close()
+
+	rows, _ = db.Query("SELECT seq")
+	if got := scanInt64(t, rows); got != 2 {
+		t.Fatalf("second call: want 2, got %d", got)
+	}
+	rows.Close()
+
+	rows, _ = db.Query("SELECT seq")
+	if rows.Next() {
+		t.Fatal("third call: expected exhausted sequence to yield no rows")
+	}
+	rows.Close()
+}
+
+func TestSetResponseCycleWrapsAround(t *testing.T) {
+	db, err := sql.Open("recordriver", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	SetResponseCycle(t.Name(), "SELECT cyc",
+		&Response{Cols: []string{"a"}, Data: [][]driver.Value{{int64(1)}}},
+		&Response{Cols: []string{"a"}, Data: [][]driver.Value{{int64(2)}}},
+	)
+
+	want := []int64{1, 2, 1, 2}
+	for i, w := range want {
+		rows, err := db.Query("SELECT cyc")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := scanInt64(t, rows); got != w {
+			t.Fatalf("call %d: want %d, got %d", i, w, got)
+		}
+		rows.Close()
+	}
+}
+
+func TestSetResponsePatternPriorityAndOrder(t *testing.T) {
+	db, err := sql.Open("recordriver", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// An exact SetResponse match must win over a pattern that would also match.
+	SetResponse(t.Name(), "SELECT * FROM widgets", &Response{Cols: []string{"a"}, Data: [][]driver.Value{{int64(100)}}})
+	SetResponsePattern(t.Name(), regexp.MustCompile(`^SELECT \* FROM \w+$`), &Response{Cols: []string{"a"}, Data: [][]driver.Value{{int64(1)}}})
+	// Among patterns, the first registered match wins.
+	SetResponsePattern(t.Name(), regexp.MustCompile(`gadgets$`), &Response{Cols: []string{"a"}, Data: [][]driver.Value{{int64(2)}}})
+
+	rows, _ := db.Query("SELECT * FROM widgets")
+	if got := scanInt64(t, rows); got != 100 {
+		t.Fatalf("exact match should win: want 100, got %d", got)
+	}
+	rows.Close()
+
+	rows, _ = db.Query("SELECT * FROM gadgets")
+	if got := scanInt64(t, rows); got != 1 {
+		t.Fatalf("first registered pattern should win: want 1, got %d", got)
+	}
+	rows.Close()
+}
+
+func TestFileBackedSessionReloadsPatternsAndSequences(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	name := "file:" + path
+
+	db, err := sql.Open("recordriver", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Force a real connection (and the in-memory session that goes with it) before registering
+	// fixtures, so Close has something to flush to disk instead of leaving SetResponsePattern's
+	// ensureSession-created entry lingering in the package-level sessions map.
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+
+	SetResponsePattern(name, regexp.MustCompile(`^SELECT \* FROM widgets$`), &Response{Cols: []string{"a"}, Data: [][]driver.Value{{int64(1)}}})
+	SetResponseSequence(name, "SELECT seq",
+		&Response{Cols: []string{"a"}, Data: [][]driver.Value{{int64(10)}}},
+		&Response{Cols: []string{"a"}, Data: [][]driver.Value{{int64(20)}}},
+	)
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := Session(name); ok {
+		t.Fatal("expected the in-memory session to be gone after Close, forcing a real reload")
+	}
+
+	// Re-opening the file-backed DSN without touching SetResponse again should still serve both
+	// the pattern fixture and the remaining sequence entries.
+	db2, err := sql.Open("recordriver", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db2.Close()
+	if err := db2.Ping(); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := db2.Query("SELECT * FROM widgets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := scanInt64(t, rows); got != 1 {
+		t.Fatalf("pattern fixture not reloaded: want 1, got %d", got)
+	}
+	rows.Close()
+
+	rows, _ = db2.Query("SELECT seq")
+	if got := scanInt64(t, rows); got != 10 {
+		t.Fatalf("sequence fixture not reloaded: want 10, got %d", got)
+	}
+	rows.Close()
+
+	rows, _ = db2.Query("SELECT seq")
+	if got := scanInt64(t, rows); got != 20 {
+		t.Fatalf("sequence position not reloaded: want 20, got %d", got)
+	}
+	rows.Close()
+}
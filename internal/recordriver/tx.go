@@ -0,0 +1,96 @@
+package recordriver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"regexp"
+	"strings"
+)
+
+var (
+	beginRe             = regexp.MustCompile(`(?i)^BEGIN\b`)
+	commitRe            = regexp.MustCompile(`(?i)^COMMIT\b`)
+	rollbackRe          = regexp.MustCompile(`(?i)^ROLLBACK\b`)
+	rollbackToSavepoint = regexp.MustCompile(`(?i)^ROLLBACK\s+TO\s+SAVEPOINT\b`)
+	savepointRe         = regexp.MustCompile(`(?i)^(RELEASE\s+)?SAVEPOINT\b`)
+)
+
+// BeginTx starts and returns a new transaction, recording a BEGIN statement annotated with the
+// requested isolation level and read-only flag. It implements driver.ConnBeginTx. Cancellation
+// via ctx fails the begin without registering the transaction.
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	sessions[c.session].Statements = append(sessions[c.session].Statements, Entry{
+		SQL: beginStatement(opts),
+		Ctx: ctx,
+	})
+	return &tx{session: c.session}, nil
+}
+
+// beginStatement renders a BEGIN statement annotated with opts, matching the verbose form
+// databases emit so Transactions can be inspected without a side-channel.
+func beginStatement(opts driver.TxOptions) string {
+	var sb strings.Builder
+	sb.WriteString("BEGIN")
+	if lvl := sql.IsolationLevel(opts.Isolation); lvl != sql.LevelDefault {
+		sb.WriteString(" ISOLATION LEVEL ")
+		sb.WriteString(strings.ToUpper(lvl.String()))
+	}
+	if opts.ReadOnly {
+		sb.WriteString(" READ ONLY")
+	}
+	return sb.String()
+}
+
+// Commit commits the transaction, recording a COMMIT marker in the session's Statements.
+func (t *tx) Commit() error {
+	mu.Lock()
+	defer mu.Unlock()
+	sessions[t.session].Statements = append(sessions[t.session].Statements, Entry{SQL: "COMMIT"})
+	return nil
+}
+
+// Rollback rolls back the transaction, recording a ROLLBACK marker in the session's Statements.
+func (t *tx) Rollback() error {
+	mu.Lock()
+	defer mu.Unlock()
+	sessions[t.session].Statements = append(sessions[t.session].Statements, Entry{SQL: "ROLLBACK"})
+	return nil
+}
+
+// Transactions groups the session's recorded statements by the transaction (BEGIN...COMMIT or
+// BEGIN...ROLLBACK) that contains them; statements recorded outside any transaction are omitted.
+// SAVEPOINT, RELEASE SAVEPOINT, and ROLLBACK TO SAVEPOINT statements nest inside their
+// enclosing transaction instead of starting or ending one of their own, so gorm's use of
+// savepoints to emulate nested transactions shows up as a single grouped transaction.
+func (s *session) Transactions() [][]string {
+	var (
+		txs     [][]string
+		current []string
+		inTx    bool
+	)
+	for _, e := range s.Statements {
+		stmt := strings.TrimSpace(e.SQL)
+		switch {
+		case beginRe.MatchString(stmt):
+			current = []string{e.SQL}
+			inTx = true
+		case !inTx:
+			// Statement recorded outside any transaction; Transactions only reports grouped ones.
+		case rollbackToSavepoint.MatchString(stmt), savepointRe.MatchString(stmt):
+			current = append(current, e.SQL)
+		case commitRe.MatchString(stmt), rollbackRe.MatchString(stmt):
+			current = append(current, e.SQL)
+			txs = append(txs, current)
+			current, inTx = nil, false
+		default:
+			current = append(current, e.SQL)
+		}
+	}
+	return txs
+}
@@ -0,0 +1,129 @@
+package recordriver
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestBeginTxRendersIsolationAndReadOnly(t *testing.T) {
+	db, err := sql.Open("recordriver", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tx, err := db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	sess, ok := Session(t.Name())
+	if !ok {
+		t.Fatal("expected session to exist")
+	}
+	stmts := sess.StatementEntries()
+	if len(stmts) == 0 || stmts[0].SQL != "BEGIN ISOLATION LEVEL SERIALIZABLE READ ONLY" {
+		t.Fatalf("unexpected BEGIN statement: %+v", stmts)
+	}
+}
+
+func TestBeginTxCancellationDoesNotRegisterTransaction(t *testing.T) {
+	db, err := sql.Open("recordriver", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// Force the connection open before cancelling, so the session exists to be inspected below.
+	// database/sql returns ctx.Err() for a done context before handing out a connection.
+	if err := db.PingContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := db.BeginTx(ctx, nil); err == nil {
+		t.Fatal("expected error from cancelled context")
+	}
+
+	sess, ok := Session(t.Name())
+	if !ok {
+		t.Fatal("expected session to exist")
+	}
+	if len(sess.StatementEntries()) != 0 {
+		t.Fatalf("cancelled BeginTx should not record a BEGIN statement, got %+v", sess.StatementEntries())
+	}
+}
+
+func TestTransactionsGroupsSavepointsWithinEnclosingTransaction(t *testing.T) {
+	db, err := sql.Open("recordriver", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		"CREATE TABLE foo (id int)",
+		"SAVEPOINT sp1",
+		"INSERT INTO foo VALUES (1)",
+		"ROLLBACK TO SAVEPOINT sp1",
+		"RELEASE SAVEPOINT sp1",
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A second, independent transaction that rolls back entirely.
+	tx2, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx2.Exec("DELETE FROM foo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx2.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	sess, ok := Session(t.Name())
+	if !ok {
+		t.Fatal("expected session to exist")
+	}
+
+	txs := sess.Transactions()
+	if len(txs) != 2 {
+		t.Fatalf("want 2 transactions, got %d: %v", len(txs), txs)
+	}
+
+	want1 := []string{
+		"BEGIN",
+		"CREATE TABLE foo (id int)",
+		"SAVEPOINT sp1",
+		"INSERT INTO foo VALUES (1)",
+		"ROLLBACK TO SAVEPOINT sp1",
+		"RELEASE SAVEPOINT sp1",
+		"COMMIT",
+	}
+	if !reflect.DeepEqual(txs[0], want1) {
+		t.Fatalf("first transaction: got %v want %v", txs[0], want1)
+	}
+
+	want2 := []string{"BEGIN", "DELETE FROM foo", "ROLLBACK"}
+	if !reflect.DeepEqual(txs[1], want2) {
+		t.Fatalf("second transaction: got %v want %v", txs[1], want2)
+	}
+}